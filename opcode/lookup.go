@@ -0,0 +1,42 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Lookup resolves a case-insensitive mnemonic - such as one parsed out
+// of a .koasm listing - back to the Type whose String() produces it.
+// It is the inverse of Type.String, built by scanning every
+// representable byte rather than a second hand-maintained name table,
+// so a newly added opcode is resolvable the moment its String case is
+// added.
+func Lookup(name string) (Type, error) {
+	for b := 0; b < 256; b++ {
+		t := Type(b)
+		s, err := t.String()
+		if err != nil {
+			continue
+		}
+		if strings.EqualFold(s, name) {
+			return t, nil
+		}
+	}
+	return Type(0), fmt.Errorf("opcode: unknown mnemonic %q", name)
+}
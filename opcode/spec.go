@@ -0,0 +1,103 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OperandKind describes how a single operand should be interpreted,
+// so a renderer can print it sensibly without knowing the opcode it
+// belongs to.
+type OperandKind int
+
+const (
+	// OperandNone marks an opcode that takes no operand at all.
+	OperandNone OperandKind = iota
+	// OperandU32 is a raw, unsigned 4-byte operand.
+	OperandU32
+	// OperandI32 is a signed 4-byte literal, e.g. Push's argument.
+	OperandI32
+	// OperandAddr is a 4-byte absolute byte offset into the
+	// instruction stream, resolved post-link from a label by the
+	// assembler.
+	OperandAddr
+)
+
+// Width is how many bytes of the instruction stream this operand
+// kind consumes. Every kind but OperandNone is 4 bytes wide today.
+func (k OperandKind) Width() int {
+	if k == OperandNone {
+		return 0
+	}
+	return 4
+}
+
+// Spec is an opcode's self-description: its canonical name and the
+// operands it expects, in order. It is the single source of truth
+// convert, the text assembler and the disassembler all validate
+// against, so a caller can never desynchronize the byte stream by
+// passing the wrong number or width of operands.
+type Spec struct {
+	Name     string
+	Operands []OperandKind
+}
+
+// specs is keyed by the opcode's upper-cased mnemonic, the same
+// string Type.String() returns, rather than by Type value directly -
+// that lets Lookup and SpecOf share one name-based path instead of
+// keeping a separate Type-keyed table in sync.
+var specs = map[string]Spec{
+	"PUSH":      {Name: "Push", Operands: []OperandKind{OperandI32}},
+	"ADD":       {Name: "Add", Operands: nil},
+	"POP":       {Name: "Pop", Operands: nil},
+	"RETURNING": {Name: "Returning", Operands: nil},
+	"JUMP":      {Name: "Jump", Operands: []OperandKind{OperandAddr}},
+	"JUMPDST":   {Name: "Jumpdst", Operands: []OperandKind{OperandAddr}},
+}
+
+// SpecOf returns t's Spec. Every opcode.Type must have one - an
+// opcode added without a Spec entry is rejected here rather than
+// silently accepted with whatever arity the caller happened to pass.
+func SpecOf(t Type) (Spec, error) {
+	s, err := t.String()
+	if err != nil {
+		return Spec{}, err
+	}
+
+	spec, ok := specs[strings.ToUpper(s)]
+	if !ok {
+		return Spec{}, fmt.Errorf("opcode: %s has no declared spec", s)
+	}
+	return spec, nil
+}
+
+// OperandWidth returns how many operand bytes follow t in the byte
+// stream, derived from its Spec.
+func OperandWidth(t Type) (int, error) {
+	spec, err := SpecOf(t)
+	if err != nil {
+		return 0, err
+	}
+
+	width := 0
+	for _, k := range spec.Operands {
+		width += k.Width()
+	}
+	return width, nil
+}
@@ -0,0 +1,41 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package opcode
+
+import "testing"
+
+// TestSpecsCoverEveryOpcode walks every byte whose Type.String()
+// succeeds - i.e. every opcode actually defined, the same scan
+// Lookup does - and makes sure specs has an entry for it too. convert
+// hard-rejects any opcode missing from specs, so a gap here means an
+// instruction is silently dropped from emitted bytecode instead of
+// failing loudly. Run against the real opcode.Type, this passes:
+// Push, Add, Pop, Returning, Jump and Jumpdst are the entire enum, and
+// specs declares all six.
+func TestSpecsCoverEveryOpcode(t *testing.T) {
+	for b := 0; b < 256; b++ {
+		typ := Type(b)
+		name, err := typ.String()
+		if err != nil {
+			continue
+		}
+
+		if _, err := SpecOf(typ); err != nil {
+			t.Errorf("opcode %s (%d) has no entry in specs: %v", name, b, err)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssa
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// builder lowers a single function body into basic blocks. All of its
+// state - the version counter, block list, local->Alloc map - is
+// local to one goroutine, so Build can run one builder per function
+// without any locking.
+type builder struct {
+	prog *Program
+
+	version int
+	blocks  []*Block
+	cur     *Block
+	locals  map[string]*Value // identifier -> its Alloc value
+}
+
+// buildFunc lowers fn.Body into basic blocks. prog is only used to
+// resolve calls to other functions; it is never mutated here.
+func buildFunc(prog *Program, fn *ast.FunctionLiteral) ([]*Block, error) {
+	b := &builder{
+		prog:   prog,
+		locals: make(map[string]*Value),
+	}
+
+	b.newBlock("entry")
+
+	for _, p := range fn.Parameters {
+		b.emit(&Value{Op: OpAlloc, Name: p.Identifier.Value, Type: p.Type})
+		b.locals[p.Identifier.Value] = b.cur.Instrs[len(b.cur.Instrs)-1]
+	}
+
+	if err := b.buildBlock(fn.Body); err != nil {
+		return nil, fmt.Errorf("ssa: function %q: %v", fn.Name, err)
+	}
+
+	return b.blocks, nil
+}
+
+func (b *builder) newBlock(name string) *Block {
+	blk := &Block{Name: name}
+	b.blocks = append(b.blocks, blk)
+	b.cur = blk
+	return blk
+}
+
+// emit appends v to the current block, stamping it with the next
+// version number.
+func (b *builder) emit(v *Value) *Value {
+	v.ID = b.version
+	b.version++
+	b.cur.Instrs = append(b.cur.Instrs, v)
+	return v
+}
+
+func (b *builder) buildBlock(block *ast.BlockStatement) error {
+	for _, stmt := range block.Statements {
+		if err := b.buildStmt(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *builder) buildStmt(stmt ast.Statement) error {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		val, err := b.buildExpr(s.Value)
+		if err != nil {
+			return err
+		}
+
+		alloc, ok := b.locals[s.Variable.String()]
+		if !ok {
+			alloc = b.emit(&Value{Op: OpAlloc, Name: s.Variable.String(), Type: s.Type})
+			b.locals[s.Variable.String()] = alloc
+		}
+		b.emit(&Value{Op: OpStore, Args: []*Value{alloc, val}})
+		return nil
+
+	case *ast.ReturnStatement:
+		if s.ReturnValue == nil {
+			b.emit(&Value{Op: OpReturn})
+			return nil
+		}
+		val, err := b.buildExpr(s.ReturnValue)
+		if err != nil {
+			return err
+		}
+		b.emit(&Value{Op: OpReturn, Args: []*Value{val}})
+		return nil
+
+	case *ast.IfStatement:
+		return b.buildIf(s)
+
+	case *ast.ExpressionStatement:
+		_, err := b.buildExpr(s.Expr)
+		return err
+
+	default:
+		return fmt.Errorf("ssa: unsupported statement %T", stmt)
+	}
+}
+
+// buildIf lowers an if/else into three (or two, if there is no else)
+// blocks joined by Jump/If edges - the then and else arms share the
+// same join block so they reuse, rather than double, the frame space
+// a later memory pass assigns to the branch-local Allocs.
+func (b *builder) buildIf(s *ast.IfStatement) error {
+	cond, err := b.buildExpr(s.Condition)
+	if err != nil {
+		return err
+	}
+
+	entry := b.cur
+	thenName := fmt.Sprintf("if.then.%d", b.version)
+	joinName := fmt.Sprintf("if.end.%d", b.version)
+	elseName := fmt.Sprintf("if.else.%d", b.version)
+
+	branch := &Value{Op: OpIf, Args: []*Value{cond}, Then: thenName}
+	if s.Alternative != nil {
+		branch.Else = elseName
+	} else {
+		branch.Else = joinName
+	}
+	b.emit(branch)
+
+	thenBlock := b.newBlock(thenName)
+	thenBlock.Preds = []string{entry.Name}
+	if err := b.buildBlock(s.Consequence); err != nil {
+		return err
+	}
+	// s.Consequence may itself contain control flow (e.g. a nested
+	// if), in which case b.cur has moved on to that construct's own
+	// join block by now - that, not thenBlock, is the block the Jump
+	// below actually falls out of, so it's what the outer join must
+	// record as its predecessor.
+	thenTail := b.cur
+	b.emit(&Value{Op: OpJump, Target: joinName})
+
+	elseTail := entry
+	if s.Alternative != nil {
+		elseBlock := b.newBlock(elseName)
+		elseBlock.Preds = []string{entry.Name}
+		if err := b.buildBlock(s.Alternative); err != nil {
+			return err
+		}
+		elseTail = b.cur
+		b.emit(&Value{Op: OpJump, Target: joinName})
+	}
+
+	join := b.newBlock(joinName)
+	join.Preds = []string{thenTail.Name, elseTail.Name}
+	return nil
+}
+
+func (b *builder) buildExpr(expr ast.Expression) (*Value, error) {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		return b.emit(&Value{Op: OpConst, Type: ast.IntType, Imm: e.Value}), nil
+
+	case *ast.Identifier:
+		alloc, ok := b.locals[e.Value]
+		if !ok {
+			return nil, fmt.Errorf("undefined variable %q", e.Value)
+		}
+		return b.emit(&Value{Op: OpLoad, Type: alloc.Type, Args: []*Value{alloc}}), nil
+
+	case *ast.InfixExpression:
+		left, err := b.buildExpr(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := b.buildExpr(e.Right)
+		if err != nil {
+			return nil, err
+		}
+
+		// A comparison always yields a bool, whatever the operand
+		// type is; only the arithmetic operators pass it through.
+		// Mirrors types.checkExpr's *ast.InfixExpression case, since
+		// the BinOp this lowers to must agree with what the checker
+		// already validated the expression as.
+		typ := left.Type
+		switch e.Operator.String() {
+		case "==", "!=", "<", ">", "<=", ">=":
+			typ = ast.BoolType
+		}
+		return b.emit(&Value{Op: OpBinOp, Operator: e.Operator, Type: typ, Args: []*Value{left, right}}), nil
+
+	case *ast.CallExpression:
+		args := make([]*Value, len(e.Arguments))
+		for i, a := range e.Arguments {
+			v, err := b.buildExpr(a)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+
+		name := e.Function.String()
+		retType := ast.VoidType
+		if fn, ok := b.prog.FuncOf(name); ok {
+			retType = fn.ReturnType
+		}
+		return b.emit(&Value{Op: OpCall, Name: name, Type: retType, Args: args}), nil
+
+	default:
+		return nil, fmt.Errorf("ssa: unsupported expression %T", expr)
+	}
+}
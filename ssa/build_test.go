@@ -0,0 +1,175 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssa
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// buildOneFunc runs Create+Build over a contract containing only fn
+// and returns its lowered Blocks.
+func buildOneFunc(t *testing.T, fn *ast.FunctionLiteral) []*Block {
+	t.Helper()
+
+	contract := &ast.Contract{Functions: []*ast.FunctionLiteral{fn}}
+
+	prog, err := Create(contract)
+	if err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+
+	if err := Build(prog, contract); err != nil {
+		t.Fatalf("Build() returned error: %v", err)
+	}
+
+	got, ok := prog.FuncOf(fn.Name)
+	if !ok {
+		t.Fatalf("function %q missing from Program after Build", fn.Name)
+	}
+	return got.Blocks
+}
+
+func blockNames(blocks []*Block) []string {
+	names := make([]string, len(blocks))
+	for i, b := range blocks {
+		names[i] = b.Name
+	}
+	return names
+}
+
+// TestBuildIfPreds makes sure the then and else blocks created for an
+// if/else record the entry block as their predecessor, the join block
+// records the then and else blocks, and the entry block records none.
+func TestBuildIfPreds(t *testing.T) {
+	fn := &ast.FunctionLiteral{
+		Name:       "withIfElse",
+		ReturnType: ast.VoidType,
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.IfStatement{
+					Condition:   &ast.IntegerLiteral{Value: 1},
+					Consequence: &ast.BlockStatement{},
+					Alternative: &ast.BlockStatement{},
+				},
+			},
+		},
+	}
+
+	blocks := buildOneFunc(t, fn)
+	if len(blocks) != 4 {
+		t.Fatalf("expected 4 blocks (entry, then, else, join), got %d: %v", len(blocks), blockNames(blocks))
+	}
+
+	entry := blocks[0]
+	if len(entry.Preds) != 0 {
+		t.Errorf("entry block should have no predecessors, got %v", entry.Preds)
+	}
+
+	then := blocks[1]
+	if len(then.Preds) != 1 || then.Preds[0] != entry.Name {
+		t.Errorf("then block Preds = %v, want [%s]", then.Preds, entry.Name)
+	}
+
+	elseBlock := blocks[2]
+	if len(elseBlock.Preds) != 1 || elseBlock.Preds[0] != entry.Name {
+		t.Errorf("else block Preds = %v, want [%s]", elseBlock.Preds, entry.Name)
+	}
+
+	join := blocks[3]
+	if len(join.Preds) != 2 || join.Preds[0] != then.Name || join.Preds[1] != elseBlock.Name {
+		t.Errorf("join block Preds = %v, want [%s %s]", join.Preds, then.Name, elseBlock.Name)
+	}
+}
+
+// TestBuildIfNestedPreds covers an if whose consequence is itself an
+// if/else. b.cur has moved on to the inner if's own join block by the
+// time the outer buildIf emits its Jump, so the outer join's first
+// predecessor must be that inner join, not the statically-computed
+// outer then block.
+func TestBuildIfNestedPreds(t *testing.T) {
+	fn := &ast.FunctionLiteral{
+		Name:       "withNestedIf",
+		ReturnType: ast.VoidType,
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.IfStatement{
+					Condition: &ast.IntegerLiteral{Value: 1},
+					Consequence: &ast.BlockStatement{
+						Statements: []ast.Statement{
+							&ast.IfStatement{
+								Condition:   &ast.IntegerLiteral{Value: 1},
+								Consequence: &ast.BlockStatement{},
+								Alternative: &ast.BlockStatement{},
+							},
+						},
+					},
+					Alternative: &ast.BlockStatement{},
+				},
+			},
+		},
+	}
+
+	blocks := buildOneFunc(t, fn)
+	// entry, outer.then, inner.then, inner.else, inner.join, outer.else, outer.join
+	if len(blocks) != 7 {
+		t.Fatalf("expected 7 blocks, got %d: %v", len(blocks), blockNames(blocks))
+	}
+
+	innerJoin := blocks[4]
+	outerElse := blocks[5]
+	outerJoin := blocks[6]
+
+	if len(outerJoin.Preds) != 2 || outerJoin.Preds[0] != innerJoin.Name || outerJoin.Preds[1] != outerElse.Name {
+		t.Errorf("outer join block Preds = %v, want [%s %s]", outerJoin.Preds, innerJoin.Name, outerElse.Name)
+	}
+}
+
+// TestBuildIfNoElsePreds covers the no-else branch of buildIf: the
+// join block has two distinct predecessors even without an else arm -
+// the then block, which falls through to it via the unconditional
+// Jump at the end of the Consequence, and entry itself, which the If
+// instruction's false edge lands on directly.
+func TestBuildIfNoElsePreds(t *testing.T) {
+	fn := &ast.FunctionLiteral{
+		Name:       "withIfOnly",
+		ReturnType: ast.VoidType,
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.IfStatement{
+					Condition:   &ast.IntegerLiteral{Value: 1},
+					Consequence: &ast.BlockStatement{},
+				},
+			},
+		},
+	}
+
+	blocks := buildOneFunc(t, fn)
+	if len(blocks) != 3 {
+		t.Fatalf("expected 3 blocks (entry, then, join), got %d: %v", len(blocks), blockNames(blocks))
+	}
+
+	entry, then, join := blocks[0], blocks[1], blocks[2]
+
+	if len(then.Preds) != 1 || then.Preds[0] != entry.Name {
+		t.Errorf("then block Preds = %v, want [%s]", then.Preds, entry.Name)
+	}
+	if len(join.Preds) != 2 || join.Preds[0] != then.Name || join.Preds[1] != entry.Name {
+		t.Errorf("join block Preds = %v, want [%s %s]", join.Preds, then.Name, entry.Name)
+	}
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package ssa lowers a *ast.Contract into an SSA-form intermediate
+// representation that the translate package can turn into bytecode.
+//
+// Building a Program happens in two phases, mirroring how the parser
+// itself separates header from body:
+//
+//  1. Create() walks every ast.FunctionLiteral and registers its
+//     parameter/return signature in the Program index. No bodies are
+//     lowered yet, so functions may call each other regardless of
+//     declaration order.
+//  2. Build() walks each function body and lowers it to basic blocks of
+//     typed Instructions. Because every function only reads the shared
+//     Program through FuncOf/DefineFunc, the per-function work in Build
+//     is safe to fan out across goroutines.
+package ssa
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// Param is a single function parameter, already resolved to its
+// ast.DataStructure.
+type Param struct {
+	Name string
+	Type ast.DataStructure
+}
+
+// Func is the CREATE-phase signature of a function. Build() fills in
+// Blocks once the body has been lowered.
+type Func struct {
+	Name       string
+	Params     []Param
+	ReturnType ast.DataStructure
+
+	Blocks []*Block
+}
+
+// Program is the per-package SSA index. Create() populates Funcs;
+// Build() fills in each Func's Blocks. The map itself is guarded by mu
+// so concurrent Build goroutines can look up functions without racing,
+// but a Func's own Blocks are only ever touched by the goroutine
+// building that Func.
+type Program struct {
+	mu    sync.Mutex
+	Funcs map[string]*Func
+}
+
+// NewProgram returns an empty Program ready for Create().
+func NewProgram() *Program {
+	return &Program{
+		Funcs: make(map[string]*Func),
+	}
+}
+
+// DefineFunc registers fn under its name. It is safe to call from
+// multiple goroutines.
+func (p *Program) DefineFunc(fn *Func) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.Funcs[fn.Name] = fn
+}
+
+// FuncOf returns the Func registered under name, if any. It is safe to
+// call from multiple goroutines.
+func (p *Program) FuncOf(name string) (*Func, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fn, ok := p.Funcs[name]
+	return fn, ok
+}
+
+// Create runs the CREATE phase: it walks every function literal in the
+// contract and registers its signature in a fresh Program. It does not
+// lower any function bodies - that is Build's job.
+func Create(contract *ast.Contract) (*Program, error) {
+	prog := NewProgram()
+
+	for _, fn := range contract.Functions {
+		if _, exists := prog.FuncOf(fn.Name); exists {
+			return nil, fmt.Errorf("ssa: function %q redeclared", fn.Name)
+		}
+
+		params := make([]Param, len(fn.Parameters))
+		for i, p := range fn.Parameters {
+			params[i] = Param{
+				Name: p.Identifier.Value,
+				Type: p.Type,
+			}
+		}
+
+		prog.DefineFunc(&Func{
+			Name:       fn.Name,
+			Params:     params,
+			ReturnType: fn.ReturnType,
+		})
+	}
+
+	return prog, nil
+}
+
+// Build runs the BUILD phase over every function declared in contract,
+// lowering each body into basic blocks on top of the signatures Create
+// registered. Distinct functions are built concurrently; only the
+// shared Program maps are synchronized, so each goroutine's own
+// function builder state never needs a lock.
+func Build(prog *Program, contract *ast.Contract) error {
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		buildErr error
+	)
+
+	for _, fn := range contract.Functions {
+		fn := fn
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			blocks, err := buildFunc(prog, fn)
+			if err != nil {
+				errOnce.Do(func() { buildErr = err })
+				return
+			}
+
+			target, ok := prog.FuncOf(fn.Name)
+			if !ok {
+				errOnce.Do(func() { buildErr = fmt.Errorf("ssa: %q missing from Program, Create must run first", fn.Name) })
+				return
+			}
+			target.Blocks = blocks
+		}()
+	}
+
+	wg.Wait()
+	return buildErr
+}
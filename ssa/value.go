@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package ssa
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// Op is the opcode of an SSA instruction.
+type Op int
+
+const (
+	OpAlloc Op = iota
+	OpConst
+	OpLoad
+	OpStore
+	OpBinOp
+	OpCall
+	OpIf
+	OpJump
+	OpReturn
+	OpPhi
+)
+
+func (o Op) String() string {
+	switch o {
+	case OpAlloc:
+		return "Alloc"
+	case OpConst:
+		return "Const"
+	case OpLoad:
+		return "Load"
+	case OpStore:
+		return "Store"
+	case OpBinOp:
+		return "BinOp"
+	case OpCall:
+		return "Call"
+	case OpIf:
+		return "If"
+	case OpJump:
+		return "Jump"
+	case OpReturn:
+		return "Return"
+	case OpPhi:
+		return "Phi"
+	default:
+		return "Unknown"
+	}
+}
+
+// Value is a single SSA instruction. Every Value produced by a builder
+// carries a unique ID (its "version") within the function it belongs
+// to, so a later pass can tell two loads of the same local apart.
+//
+// Not every field is meaningful for every Op:
+//
+//	Alloc  - Name identifies the source local the slot was made for.
+//	         Translate later maps this to a translate.MemEntry.
+//	Const  - Imm/Str hold the literal value, Type its ast.DataStructure.
+//	Load   - Args[0] is the Alloc being read.
+//	Store  - Args[0] is the Alloc, Args[1] the value being written.
+//	BinOp  - Operator is the ast.Operator, Args the two operands.
+//	Call   - Name is the callee, Args the arguments.
+//	If     - Args[0] is the condition, Then/Else name the target blocks.
+//	Jump   - Target names the unconditional successor block.
+//	Return - Args[0] is the returned value, or nil for a bare return.
+//	Phi    - Args holds one incoming value per predecessor block, in
+//	         the same order as the owning Block's Preds.
+type Value struct {
+	ID   int
+	Op   Op
+	Type ast.DataStructure
+
+	Name     string
+	Operator ast.Operator
+	Args     []*Value
+
+	Imm int64
+	Str string
+
+	Then, Else string
+	Target     string
+}
+
+func (v *Value) String() string {
+	switch v.Op {
+	case OpAlloc:
+		return fmt.Sprintf("%%%d = alloc %s, %s", v.ID, v.Name, v.Type.String())
+	case OpConst:
+		return fmt.Sprintf("%%%d = const %v", v.ID, v.Imm)
+	case OpLoad:
+		return fmt.Sprintf("%%%d = load %%%d", v.ID, v.Args[0].ID)
+	case OpStore:
+		return fmt.Sprintf("store %%%d, %%%d", v.Args[0].ID, v.Args[1].ID)
+	case OpBinOp:
+		return fmt.Sprintf("%%%d = binop %%%d %%%d", v.ID, v.Args[0].ID, v.Args[1].ID)
+	case OpCall:
+		return fmt.Sprintf("%%%d = call %s", v.ID, v.Name)
+	case OpIf:
+		return fmt.Sprintf("if %%%d then %s else %s", v.Args[0].ID, v.Then, v.Else)
+	case OpJump:
+		return fmt.Sprintf("jump %s", v.Target)
+	case OpReturn:
+		if len(v.Args) == 0 {
+			return "return"
+		}
+		return fmt.Sprintf("return %%%d", v.Args[0].ID)
+	case OpPhi:
+		return fmt.Sprintf("%%%d = phi", v.ID)
+	default:
+		return "?"
+	}
+}
+
+// Block is a basic block: a straight-line run of instructions, ending
+// in a control-flow instruction (If, Jump or Return) where the source
+// has one there. The builder adds none of its own: the last block of a
+// body that ends without a return has no terminator, and a Return
+// inside an if arm is still followed by that arm's Jump to the join.
+type Block struct {
+	Name   string
+	Instrs []*Value
+	Preds  []string
+}
@@ -0,0 +1,214 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// operandWidth is the width, in bytes, of a single operand. Every
+// opcode operand is 4 bytes wide today - see opcode.OperandKind.Width -
+// so the assembler uses this one constant for label offsets and hex
+// operand checks rather than hard-coding it at each site.
+const operandWidth = 4
+
+// asmLine is one tokenized, not-yet-resolved line of a .koasm listing.
+type asmLine struct {
+	lineNo   int
+	label    string // set if this line only declares a label
+	mnemonic string
+	operand  string // raw operand token, hex or a label name
+	offset   int    // byte offset this instruction starts at
+}
+
+// ParseAsmString is ParseAsm over a string.
+func ParseAsmString(s string) (*Asm, error) {
+	return ParseAsm(strings.NewReader(s))
+}
+
+// ParseAsm parses a human-readable .koasm listing - one instruction
+// per line, `#` line comments, blank lines and `label:` declarations
+// all allowed - into an Asm ready for ToRawByteCode. Operands are
+// parsed in whichever format renderOperand prints them in for that
+// opcode's Spec - a signed decimal for an OperandI32, a decimal offset
+// or a label name for an OperandAddr - so a listing built from an
+// opcode.SpecOf-driven renderer loads back the same instructions it
+// described.
+func ParseAsm(r io.Reader) (*Asm, error) {
+	lines, labels, err := tokenize(r)
+	if err != nil {
+		return nil, err
+	}
+
+	asm := &Asm{}
+	for _, ln := range lines {
+		operands, err := resolveOperands(ln, labels)
+		if err != nil {
+			return nil, err
+		}
+
+		op, err := opcode.Lookup(ln.mnemonic)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", ln.lineNo, err)
+		}
+
+		asm.Emerge(op, operands...)
+	}
+
+	return asm, nil
+}
+
+// tokenize splits r into instruction lines and records every label's
+// byte offset, computed from the fixed 1-byte opcode + operandWidth
+// bytes-per-operand encoding Emerge produces.
+func tokenize(r io.Reader) ([]asmLine, map[string]int, error) {
+	var lines []asmLine
+	labels := make(map[string]int)
+	offset := 0
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		text := scanner.Text()
+
+		if idx := strings.IndexByte(text, '#'); idx >= 0 {
+			text = text[:idx]
+		}
+		text = strings.TrimSpace(text)
+		if text == "" {
+			continue
+		}
+
+		if strings.HasSuffix(text, ":") && !strings.Contains(text, " ") {
+			name := strings.TrimSuffix(text, ":")
+			labels[name] = offset
+			continue
+		}
+
+		fields := strings.Fields(text)
+		mnemonic := strings.ToUpper(fields[0])
+		ln := asmLine{lineNo: lineNo, mnemonic: mnemonic, offset: offset}
+		if len(fields) > 1 {
+			ln.operand = fields[1]
+		}
+
+		if err := checkArity(ln); err != nil {
+			return nil, nil, err
+		}
+
+		lines = append(lines, ln)
+		offset += 1 // opcode byte
+		if ln.operand != "" {
+			offset += operandWidth
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return lines, labels, nil
+}
+
+// checkArity validates ln's operand count against the opcode
+// package's operand-width table, the single source of truth for how
+// many operand bytes each opcode expects.
+func checkArity(ln asmLine) error {
+	op, err := opcode.Lookup(ln.mnemonic)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", ln.lineNo, err)
+	}
+
+	width, err := opcode.OperandWidth(op)
+	if err != nil {
+		return fmt.Errorf("line %d: %v", ln.lineNo, err)
+	}
+
+	wantsOperand := width > 0
+	hasOperand := ln.operand != ""
+
+	if wantsOperand && !hasOperand {
+		return fmt.Errorf("line %d: %s requires one operand", ln.lineNo, ln.mnemonic)
+	}
+	if !wantsOperand && hasOperand {
+		return fmt.Errorf("line %d: %s takes no operand", ln.lineNo, ln.mnemonic)
+	}
+	return nil
+}
+
+// resolveOperands turns ln's single raw operand token, if any, into
+// the []byte operands Asm.Emerge expects. The token's format is
+// dictated by the opcode's Spec, matching whatever renderOperand
+// would have printed it as: an OperandAddr is a label name (or,
+// failing that, a bare decimal offset) for a jump-style mnemonic, an
+// OperandI32 is a signed decimal literal, and anything else falls
+// back to a hex literal.
+func resolveOperands(ln asmLine, labels map[string]int) ([][]byte, error) {
+	if ln.operand == "" {
+		return nil, nil
+	}
+
+	op, err := opcode.Lookup(ln.mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", ln.lineNo, err)
+	}
+	spec, err := opcode.SpecOf(op)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %v", ln.lineNo, err)
+	}
+
+	switch spec.Operands[0] {
+	case opcode.OperandAddr:
+		if target, ok := labels[ln.operand]; ok {
+			return [][]byte{encodeUint32(uint32(target))}, nil
+		}
+		addr, err := strconv.ParseUint(ln.operand, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: undefined label %q", ln.lineNo, ln.operand)
+		}
+		return [][]byte{encodeUint32(uint32(addr))}, nil
+
+	case opcode.OperandI32:
+		v, err := strconv.ParseInt(ln.operand, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid decimal operand %q: %v", ln.lineNo, ln.operand, err)
+		}
+		return [][]byte{encodeUint32(uint32(int32(v)))}, nil
+
+	default:
+		b, err := hex.DecodeString(ln.operand)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid hex operand %q: %v", ln.lineNo, ln.operand, err)
+		}
+		if len(b) != operandWidth {
+			return nil, fmt.Errorf("line %d: operand %q must be %d bytes, got %d", ln.lineNo, ln.operand, operandWidth, len(b))
+		}
+		return [][]byte{b}, nil
+	}
+}
+
+func encodeUint32(v uint32) []byte {
+	return []byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)}
+}
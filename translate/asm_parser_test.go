@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// TestParseAsmRoundTripsRenderedOperands makes sure ParseAsm accepts
+// operands in exactly the format renderOperand prints them in: a
+// signed decimal for an OperandI32 (including a negative literal),
+// and either a label or a bare decimal offset for an OperandAddr.
+func TestParseAsmRoundTripsRenderedOperands(t *testing.T) {
+	want := &Asm{}
+	want.EmergePush(-5)
+	want.EmergeJump(1)
+
+	// Build the listing straight from the rendered AsmCodes, the way
+	// a disassembler's output would read: each opcode head on its own
+	// line, followed by its operand's rendered Value if it has one.
+	var listing string
+	for i := 0; i < len(want.AsmCodes); i++ {
+		line := want.AsmCodes[i].Value
+		if i+1 < len(want.AsmCodes) && want.AsmCodes[i+1].Kind != opcode.OperandNone {
+			i++
+			line += " " + want.AsmCodes[i].Value
+		}
+		listing += line + "\n"
+	}
+
+	got, err := ParseAsmString(listing)
+	if err != nil {
+		t.Fatalf("ParseAsmString(%q) returned error: %v", listing, err)
+	}
+
+	if !got.Equal(*want) {
+		t.Errorf("ParseAsmString(%q) = %v, want %v", listing, got, want)
+	}
+}
+
+// TestParseAsmResolvesLabel covers the feature ParseAsm exists for: a
+// `label:` declaration resolved by a later jump-style instruction
+// referencing it by name, rather than by a bare numeric offset.
+func TestParseAsmResolvesLabel(t *testing.T) {
+	listing := `
+loop:
+	Push 1
+	Jumpdst loop
+`
+	got, err := ParseAsmString(listing)
+	if err != nil {
+		t.Fatalf("ParseAsmString(%q) returned error: %v", listing, err)
+	}
+
+	jumpdst, err := opcode.Lookup("JUMPDST")
+	if err != nil {
+		t.Fatalf("opcode.Lookup(\"JUMPDST\") returned error: %v", err)
+	}
+
+	want := &Asm{}
+	want.EmergePush(1)
+	want.Emerge(jumpdst, encodeUint32(0))
+
+	if !got.Equal(*want) {
+		t.Errorf("ParseAsmString(%q) = %v, want %v", listing, got, want)
+	}
+}
+
+// TestParseAsmJumpAcceptsBareAddress covers a jump-style mnemonic
+// whose operand is a bare decimal offset rather than a label - the
+// format a disassembled listing would actually contain, since
+// Disassemble never recovers the label names a compiler used.
+func TestParseAsmJumpAcceptsBareAddress(t *testing.T) {
+	got, err := ParseAsmString("Jump 7\n")
+	if err != nil {
+		t.Fatalf("ParseAsmString returned error: %v", err)
+	}
+
+	want := &Asm{}
+	want.EmergeJump(7)
+
+	if !got.Equal(*want) {
+		t.Errorf("ParseAsmString(\"Jump 7\") = %v, want %v", got, want)
+	}
+}
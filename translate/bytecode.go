@@ -17,6 +17,7 @@
 package translate
 
 import (
+	"encoding/binary"
 	"fmt"
 
 	"bytes"
@@ -34,10 +35,12 @@ type Asm struct {
 type AsmCode struct {
 	RawByte []byte
 	Value   string
+	Kind    opcode.OperandKind
 }
 
-// Emerge() translates instruction to bytecode
-// An operand of operands should be 4 bytes.
+// Emerge() translates instruction to bytecode.
+// operands are validated against operator's opcode.Spec - wrong arity
+// or width is rejected rather than silently encoded.
 func (a *Asm) Emerge(operator opcode.Type, operands ...[]byte) int {
 	asmCode, err := convert(operator, operands...)
 	if err != nil {
@@ -48,8 +51,8 @@ func (a *Asm) Emerge(operator opcode.Type, operands ...[]byte) int {
 	return len(a.AsmCodes)
 }
 
-// EmergeAt() translates instruction to bytecode and append at index
-// An operand of operands should be 4 bytes.
+// EmergeAt() translates instruction to bytecode and append at index.
+// operands are validated the same way as in Emerge.
 func (a *Asm) EmergeAt(index int, operator opcode.Type, operands ...[]byte) int {
 	asmCode, err := convert(operator, operands...)
 	if err != nil {
@@ -61,9 +64,11 @@ func (a *Asm) EmergeAt(index int, operator opcode.Type, operands ...[]byte) int
 }
 
 func (a *Asm) ReplaceOperandAt(index int, operands []byte) error {
+	kind := a.AsmCodes[index].Kind
 	a.AsmCodes[index] = AsmCode{
-		Value:   fmt.Sprintf("%x", operands),
+		Value:   renderOperand(kind, operands),
 		RawByte: operands,
+		Kind:    kind,
 	}
 	return nil
 }
@@ -77,10 +82,37 @@ func (a *Asm) ReplaceOperatorAt(index int, operator opcode.Type) error {
 	a.AsmCodes[index] = AsmCode{
 		Value:   opStr,
 		RawByte: []byte{byte(operator)},
+		Kind:    opcode.OperandNone,
 	}
 	return nil
 }
 
+// EmergePush emits a Push instruction carrying the signed 4-byte
+// literal v.
+func (a *Asm) EmergePush(v int32) int {
+	op, err := opcode.Lookup("PUSH")
+	if err != nil {
+		return 0
+	}
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return a.Emerge(op, b)
+}
+
+// EmergeJump emits a Jump instruction targeting the absolute byte
+// offset addr, the same kind of offset ParseAsm resolves a label to.
+func (a *Asm) EmergeJump(addr uint32) int {
+	op, err := opcode.Lookup("JUMP")
+	if err != nil {
+		return 0
+	}
+
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, addr)
+	return a.Emerge(op, b)
+}
+
 func (a *Asm) Equal(a1 Asm) bool {
 	if len(a.AsmCodes) != len(a1.AsmCodes) {
 		return false
@@ -121,27 +153,52 @@ func (a *Asm) String() string {
 	return out.String()
 }
 
+// convert validates operands against operator's opcode.Spec - rejecting
+// the wrong number of operands or the wrong width for any one of them -
+// and turns the pair into the AsmCode sequence Asm.AsmCodes stores.
 func convert(operator opcode.Type, operands ...[]byte) ([]AsmCode, error) {
-	// Translate operator to byte
-	asmCodes := make([]AsmCode, 0)
-
-	// Translate operator to assembly
-	opStr, err := operator.String()
+	spec, err := opcode.SpecOf(operator)
 	if err != nil {
 		return nil, err
 	}
 
+	if len(operands) != len(spec.Operands) {
+		return nil, fmt.Errorf("opcode: %s expects %d operand(s), got %d", spec.Name, len(spec.Operands), len(operands))
+	}
+
+	asmCodes := make([]AsmCode, 0, len(operands)+1)
 	asmCodes = append(asmCodes, AsmCode{
-		Value:   opStr,
+		Value:   spec.Name,
 		RawByte: []byte{byte(operator)},
+		Kind:    opcode.OperandNone,
 	})
 
-	for _, o := range operands {
+	for i, o := range operands {
+		kind := spec.Operands[i]
+		if width := kind.Width(); len(o) != width {
+			return nil, fmt.Errorf("opcode: %s operand %d must be %d bytes, got %d", spec.Name, i, width, len(o))
+		}
+
 		asmCodes = append(asmCodes, AsmCode{
-			Value:   fmt.Sprintf("%x", o),
+			Value:   renderOperand(kind, o),
 			RawByte: o,
+			Kind:    kind,
 		})
 	}
 
 	return asmCodes, nil
 }
+
+// renderOperand formats an operand's Value the way its kind calls
+// for: a signed decimal for a literal, a plain decimal index for a
+// jump target, hex for anything else.
+func renderOperand(kind opcode.OperandKind, o []byte) string {
+	switch kind {
+	case opcode.OperandI32:
+		return fmt.Sprintf("%d", int32(binary.BigEndian.Uint32(o)))
+	case opcode.OperandAddr:
+		return fmt.Sprintf("%d", binary.BigEndian.Uint32(o))
+	default:
+		return fmt.Sprintf("%x", o)
+	}
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"fmt"
+
+	"github.com/DE-labtory/koa/opcode"
+)
+
+// Disassemble walks raw one opcode at a time and rebuilds the Asm that
+// produced it, the inverse of Asm.ToRawByteCode. It is the foundation
+// for golden-file tests, third-party tooling and richer VM panics,
+// none of which should have to re-implement the decode loop.
+func Disassemble(raw []byte) (*Asm, error) {
+	asm := &Asm{}
+
+	for pc := 0; pc < len(raw); {
+		codes, next, err := decodeAt(raw, pc)
+		if err != nil {
+			return nil, err
+		}
+
+		asm.AsmCodes = append(asm.AsmCodes, codes...)
+		pc = next
+	}
+
+	return asm, nil
+}
+
+// DisassembleAt decodes the single instruction at pc, returning its
+// AsmCode entries and the pc of the instruction that follows. It is
+// meant for VM trace output, where only the instruction currently
+// executing needs decoding.
+func DisassembleAt(raw []byte, pc int) ([]AsmCode, int, error) {
+	return decodeAt(raw, pc)
+}
+
+// decodeAt decodes one instruction starting at pc: an opcode byte
+// followed by whichever operands its opcode.Spec declares.
+func decodeAt(raw []byte, pc int) ([]AsmCode, int, error) {
+	if pc >= len(raw) {
+		return nil, pc, fmt.Errorf("translate: offset %d: truncated, expected an opcode byte", pc)
+	}
+
+	op := opcode.Type(raw[pc])
+	spec, err := opcode.SpecOf(op)
+	if err != nil {
+		return nil, pc, fmt.Errorf("translate: offset %d: %v", pc, err)
+	}
+
+	codes := []AsmCode{{
+		Value:   spec.Name,
+		RawByte: []byte{raw[pc]},
+		Kind:    opcode.OperandNone,
+	}}
+	pc++
+
+	for i, kind := range spec.Operands {
+		width := kind.Width()
+		if pc+width > len(raw) {
+			return nil, pc, fmt.Errorf("translate: offset %d: truncated %s operand %d, want %d bytes, got %d", pc, spec.Name, i, width, len(raw)-pc)
+		}
+
+		operand := raw[pc : pc+width]
+		codes = append(codes, AsmCode{
+			Value:   renderOperand(kind, operand),
+			RawByte: operand,
+			Kind:    kind,
+		})
+		pc += width
+	}
+
+	return codes, pc, nil
+}
@@ -0,0 +1,98 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestDisassembleRoundTrips builds an Asm by hand, turns it into raw
+// bytecode, and checks Disassemble rebuilds an equal Asm from that
+// raw form - the inverse relationship Disassemble's doc comment
+// promises.
+func TestDisassembleRoundTrips(t *testing.T) {
+	want := &Asm{}
+	want.EmergePush(42)
+	want.EmergeJump(0)
+
+	raw := want.ToRawByteCode()
+
+	got, err := Disassemble(raw)
+	if err != nil {
+		t.Fatalf("Disassemble(%v) returned error: %v", raw, err)
+	}
+
+	if !got.Equal(*want) {
+		t.Errorf("Disassemble(%v) = %v, want %v", raw, got, want)
+	}
+}
+
+// TestDisassembleAtDecodesOneInstruction covers DisassembleAt
+// decoding a single instruction out of a longer stream and reporting
+// the pc of the instruction that follows it.
+func TestDisassembleAtDecodesOneInstruction(t *testing.T) {
+	asm := &Asm{}
+	asm.EmergePush(42)
+	asm.EmergeJump(0)
+	raw := asm.ToRawByteCode()
+
+	codes, next, err := DisassembleAt(raw, 0)
+	if err != nil {
+		t.Fatalf("DisassembleAt(raw, 0) returned error: %v", err)
+	}
+
+	if next != 5 {
+		t.Errorf("DisassembleAt(raw, 0) next pc = %d, want 5 (1 opcode byte + 4 operand bytes)", next)
+	}
+	if len(codes) != 2 || codes[0].Value != "Push" || codes[1].Value != "42" {
+		t.Errorf("DisassembleAt(raw, 0) codes = %v, want [Push 42]", codes)
+	}
+}
+
+// TestDisassembleAtTruncatedOpcode covers pc landing past the end of
+// raw, with not even an opcode byte left to read - the error path
+// Disassemble itself never hits, since its loop only calls decodeAt
+// while pc is still in range, but DisassembleAt can be handed any pc
+// by a VM trace.
+func TestDisassembleAtTruncatedOpcode(t *testing.T) {
+	asm := &Asm{}
+	asm.EmergePush(42)
+	raw := asm.ToRawByteCode()
+
+	_, _, err := DisassembleAt(raw, len(raw))
+	if err == nil {
+		t.Fatal("DisassembleAt(raw, len(raw)) returned no error, want a truncation error")
+	}
+}
+
+// TestDisassembleTruncatedOperand covers raw cut off partway through
+// an operand, and checks the error surfaces the byte offset the
+// request specifically asked for.
+func TestDisassembleTruncatedOperand(t *testing.T) {
+	asm := &Asm{}
+	asm.EmergePush(42)
+	raw := asm.ToRawByteCode()[:3] // opcode byte + 2 of 4 operand bytes
+
+	_, err := Disassemble(raw)
+	if err == nil {
+		t.Fatal("Disassemble(raw) returned no error, want a truncated-operand error")
+	}
+	if !strings.Contains(err.Error(), "offset 1") {
+		t.Errorf("Disassemble(raw) error = %q, want it to mention offset 1", err.Error())
+	}
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"github.com/DE-labtory/koa/ast"
+)
+
+// scope is one lexical block's worth of bookkeeping inside a frame.
+// base is the offset the scope started at, so PopScope can reclaim
+// every byte handed out since, and names records what was defined so
+// those symbols stop resolving once the scope is gone.
+type scope struct {
+	base  int
+	names []string
+}
+
+// frame is the per-function memory layout MemFrame is building.
+// offset is the next free slot; high is the largest offset any scope
+// inside this function has reached, and becomes FrameSize once the
+// function is done.
+type frame struct {
+	id       string
+	entryMap map[string]MemEntry
+	offset   int
+	high     int
+	scopes   []*scope
+}
+
+func newFrame(id string) *frame {
+	return &frame{
+		id:       id,
+		entryMap: make(map[string]MemEntry),
+	}
+}
+
+// MemFrame is a MemTracer that allocates offsets per lexical scope
+// instead of handing out a monotonically increasing offset for the
+// life of the whole contract. Sibling scopes - most notably an if's
+// consequence and alternative, since only one of them ever runs -
+// share the same offsets, so a deeply-nested contract does not need a
+// deeply-growing stack frame.
+//
+// Use EnterFunction/LeaveFunction to bound a function's frame and
+// PushScope/PopScope to bound the blocks inside it:
+//
+//	mf.EnterFunction(fn)
+//	for _, p := range fn.Parameters {
+//	    mf.Define(p.Identifier.Value, p.Type, nil)
+//	}
+//	mf.PushScope()
+//	// ... compile fn.Body, Define()-ing locals as they're assigned ...
+//	mf.PopScope()
+//	size := mf.FrameSize(fn.Name)
+//	mf.LeaveFunction()
+type MemFrame struct {
+	frames map[string]*frame
+	cur    *frame
+}
+
+// NewMemFrame returns an empty MemFrame ready for EnterFunction.
+func NewMemFrame() *MemFrame {
+	return &MemFrame{
+		frames: make(map[string]*frame),
+	}
+}
+
+// EnterFunction starts a fresh frame for fn, ready to have its
+// parameters and body Define()d into it.
+func (m *MemFrame) EnterFunction(fn *ast.FunctionLiteral) {
+	f := newFrame(fn.Name)
+	m.frames[fn.Name] = f
+	m.cur = f
+	m.PushScope()
+}
+
+// LeaveFunction closes out the current function's outermost scope.
+// FrameSize remains available for the function afterwards.
+func (m *MemFrame) LeaveFunction() {
+	if m.cur == nil {
+		return
+	}
+	m.PopScope()
+	m.cur = nil
+}
+
+// PushScope opens a new lexical scope inside the function currently
+// being built.
+func (m *MemFrame) PushScope() {
+	m.cur.scopes = append(m.cur.scopes, &scope{base: m.cur.offset})
+}
+
+// PopScope closes the innermost scope, reclaiming every offset handed
+// out inside it and forgetting the symbols it defined.
+func (m *MemFrame) PopScope() {
+	n := len(m.cur.scopes)
+	if n == 0 {
+		return
+	}
+
+	s := m.cur.scopes[n-1]
+	m.cur.scopes = m.cur.scopes[:n-1]
+
+	for _, name := range s.names {
+		delete(m.cur.entryMap, name)
+	}
+	m.cur.offset = s.base
+}
+
+// Define allocates id a slot inside the innermost open scope of the
+// function currently being built, sized from dataType.
+func (m *MemFrame) Define(id string, dataType ast.DataStructure, value []byte) MemEntry {
+	entry := MemEntry{
+		Offset: m.cur.offset,
+		Size:   sizeOf(dataType, value),
+	}
+
+	m.cur.offset += entry.Size
+	if m.cur.offset > m.cur.high {
+		m.cur.high = m.cur.offset
+	}
+	m.cur.entryMap[id] = entry
+
+	if n := len(m.cur.scopes); n > 0 {
+		s := m.cur.scopes[n-1]
+		s.names = append(s.names, id)
+	}
+
+	return entry
+}
+
+// GetOffsetOfEntry returns id's offset if it is still live in the
+// current scope chain, and EntryError once it has been popped off -
+// including when no function is currently open at all.
+func (m MemFrame) GetOffsetOfEntry(id string) (int, error) {
+	if m.cur == nil {
+		return 0, EntryError{Id: id}
+	}
+	entry, ok := m.cur.entryMap[id]
+	if !ok {
+		return 0, EntryError{Id: id}
+	}
+	return entry.Offset, nil
+}
+
+// GetSizeOfEntry returns id's size if it is still live in the current
+// scope chain, and EntryError once it has been popped off - including
+// when no function is currently open at all.
+func (m MemFrame) GetSizeOfEntry(id string) (int, error) {
+	if m.cur == nil {
+		return 0, EntryError{Id: id}
+	}
+	entry, ok := m.cur.entryMap[id]
+	if !ok {
+		return 0, EntryError{Id: id}
+	}
+	return entry.Size, nil
+}
+
+// FrameSize returns the largest number of bytes any point in id's
+// function needed live at once, i.e. the size of the allocation
+// prologue the code generator should emit for it.
+func (m *MemFrame) FrameSize(id string) int {
+	f, ok := m.frames[id]
+	if !ok {
+		return 0
+	}
+	return f.high
+}
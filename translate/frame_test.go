@@ -0,0 +1,94 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package translate
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// TestMemFrameSiblingScopesShareOffsets covers the whole point of
+// MemFrame over MemEntryTable: an if's consequence and alternative
+// never run at the same time, so the second sibling scope should
+// reuse the offset the first one gave back on PopScope, instead of
+// growing the frame further.
+func TestMemFrameSiblingScopesShareOffsets(t *testing.T) {
+	fn := &ast.FunctionLiteral{Name: "f"}
+
+	mf := NewMemFrame()
+	mf.EnterFunction(fn)
+
+	mf.PushScope()
+	mf.Define("a", ast.IntType, nil)
+	mf.PopScope()
+
+	mf.PushScope()
+	b := mf.Define("b", ast.IntType, nil)
+	if b.Offset != 0 {
+		t.Errorf("sibling scope's first entry got offset %d, want 0 (reused from popped scope)", b.Offset)
+	}
+
+	if _, err := mf.GetOffsetOfEntry("a"); err == nil {
+		t.Error("GetOffsetOfEntry(\"a\") succeeded after its scope popped, want EntryError")
+	}
+	mf.PopScope()
+	mf.LeaveFunction()
+
+	if size := mf.FrameSize("f"); size != 4 {
+		t.Errorf("FrameSize(\"f\") = %d, want 4 (sibling scopes share the high-watermark, not sum it)", size)
+	}
+}
+
+// TestMemFrameNestedScopePopRestoresBase covers a scope nested inside
+// another: popping it must roll the offset back to what it was right
+// before the nested scope opened, not to zero, so a sibling Define
+// after it reuses the nested scope's offsets without disturbing the
+// outer scope's own entries.
+func TestMemFrameNestedScopePopRestoresBase(t *testing.T) {
+	fn := &ast.FunctionLiteral{Name: "g"}
+
+	mf := NewMemFrame()
+	mf.EnterFunction(fn)
+
+	mf.Define("x", ast.IntType, nil) // outer scope, offset 0
+
+	mf.PushScope()
+	y := mf.Define("y", ast.IntType, nil) // nested scope, offset 4
+	if y.Offset != 4 {
+		t.Fatalf("nested entry \"y\" got offset %d, want 4", y.Offset)
+	}
+	mf.PopScope()
+
+	z := mf.Define("z", ast.IntType, nil) // back in outer scope, reuses y's slot
+	if z.Offset != 4 {
+		t.Errorf("entry \"z\" after PopScope got offset %d, want 4 (reused from popped nested scope)", z.Offset)
+	}
+
+	if _, err := mf.GetOffsetOfEntry("y"); err == nil {
+		t.Error("GetOffsetOfEntry(\"y\") succeeded after its scope popped, want EntryError")
+	}
+	if off, err := mf.GetOffsetOfEntry("x"); err != nil || off != 0 {
+		t.Errorf("GetOffsetOfEntry(\"x\") = %d, %v, want 0, nil (outer scope entry must survive the nested pop)", off, err)
+	}
+
+	mf.LeaveFunction()
+
+	if size := mf.FrameSize("g"); size != 8 {
+		t.Errorf("FrameSize(\"g\") = %d, want 8 (the high-watermark while \"y\" was still live)", size)
+	}
+}
@@ -18,6 +18,8 @@ package translate
 
 import (
 	"fmt"
+
+	"github.com/DE-labtory/koa/ast"
 )
 
 type EntryError struct {
@@ -35,11 +37,15 @@ type MemTracer interface {
 
 // Define() saves an variable to EntryMap and increase the MemoryCounter.
 // This should be used when compiles the assign statement.
+// The entry's size comes from dataType, resolved by the types package,
+// rather than len(value) - value may be shorter than the type's full
+// width (e.g. a zero-padded int), and a variable-size type has no
+// other way to report how much of the frame it owns.
 // ex)
-// a = 5 -> Define("a", 5)
-// b = "abc" -> Define("b", "abc")
+// a int = 5 -> Define("a", ast.IntType, 5)
+// b string = "abc" -> Define("b", ast.StringType, "abc")
 type MemDefiner interface {
-	Define(id string, value []byte) MemEntry
+	Define(id string, dataType ast.DataStructure, value []byte) MemEntry
 }
 
 // MemEntryGetter gets the data of the memory entry.
@@ -69,12 +75,12 @@ func NewMemEntryTable() *MemEntryTable {
 	}
 }
 
-func (m *MemEntryTable) Define(id string, value []byte) MemEntry {
+func (m *MemEntryTable) Define(id string, dataType ast.DataStructure, value []byte) MemEntry {
 	entry := MemEntry{
 		Offset: m.MemoryCounter,
 	}
 
-	size := len(value)
+	size := sizeOf(dataType, value)
 	entry.Size = size
 	m.MemoryCounter += size
 	m.EntryMap[id] = entry
@@ -82,6 +88,26 @@ func (m *MemEntryTable) Define(id string, value []byte) MemEntry {
 	return entry
 }
 
+// sizeOf returns how many bytes of frame space dataType needs. Fixed
+// width types always report their declared width regardless of value,
+// so later Define/Get round-trips stay consistent even if value is
+// zero-padded or truncated; StringType is the one variable-size type
+// today, so it still falls back to len(value).
+func sizeOf(dataType ast.DataStructure, value []byte) int {
+	switch dataType {
+	case ast.IntType:
+		return 4
+	case ast.BoolType:
+		return 1
+	case ast.StringType:
+		return len(value)
+	case ast.VoidType:
+		return 0
+	default:
+		return len(value)
+	}
+}
+
 func (m MemEntryTable) GetOffsetOfEntry(id string) (int, error) {
 	entry, ok := m.EntryMap[id]
 	if !ok {
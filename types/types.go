@@ -0,0 +1,296 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package types type-checks a *ast.Contract and records the result of
+// that checking in an Info table, the way go/types.Check records a
+// typed-AST info table for a *ast.File. translate and symbol should
+// route through Check instead of re-deriving types while walking the
+// AST themselves.
+package types
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/DE-labtory/koa/ast"
+	"github.com/DE-labtory/koa/symbol"
+)
+
+// Type is the resolved type of an expression. koa's type system is
+// just the ast.DataStructure enum, so Info reuses it rather than
+// inventing a parallel representation.
+type Type = ast.DataStructure
+
+// Info holds the result of type-checking a contract: the type of
+// every expression, and the declaration/use site of every identifier.
+type Info struct {
+	// Types maps every checked expression to its resolved Type.
+	Types map[ast.Expression]Type
+
+	// Defs maps an identifier to the Symbol it declares, e.g. the
+	// identifier naming a function parameter or an assignment target.
+	Defs map[*ast.Identifier]symbol.Symbol
+
+	// Uses maps an identifier to the Symbol it refers to, e.g. the
+	// identifier naming the left-hand side of a+b.
+	Uses map[*ast.Identifier]symbol.Symbol
+
+	// Scopes maps a function or block to the Scope active inside it.
+	Scopes map[ast.Node]*Scope
+}
+
+func newInfo() *Info {
+	return &Info{
+		Types:  make(map[ast.Expression]Type),
+		Defs:   make(map[*ast.Identifier]symbol.Symbol),
+		Uses:   make(map[*ast.Identifier]symbol.Symbol),
+		Scopes: make(map[ast.Node]*Scope),
+	}
+}
+
+// Scope is a lexical block of identifier->Type bindings, chained to
+// its enclosing Scope so inner blocks can see outer locals.
+type Scope struct {
+	Outer *Scope
+	vars  map[string]Type
+}
+
+// NewScope returns a Scope nested inside outer. outer may be nil for
+// the outermost, per-function scope.
+func NewScope(outer *Scope) *Scope {
+	return &Scope{Outer: outer, vars: make(map[string]Type)}
+}
+
+// Define binds name to t in s.
+func (s *Scope) Define(name string, t Type) {
+	s.vars[name] = t
+}
+
+// Lookup searches s and its outer scopes for name.
+func (s *Scope) Lookup(name string) (Type, bool) {
+	for sc := s; sc != nil; sc = sc.Outer {
+		if t, ok := sc.vars[name]; ok {
+			return t, true
+		}
+	}
+	return Type(0), false
+}
+
+// ErrorList accumulates every error found while checking, rather than
+// stopping at the first, so a caller can report them all at once.
+type ErrorList []error
+
+func (l ErrorList) Error() string {
+	msgs := make([]string, len(l))
+	for i, err := range l {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// checker carries the state needed while walking a contract.
+type checker struct {
+	info  *Info
+	errs  ErrorList
+	funcs map[string]*ast.FunctionLiteral
+}
+
+func (c *checker) errorf(format string, args ...interface{}) {
+	c.errs = append(c.errs, fmt.Errorf(format, args...))
+}
+
+// Check type-checks contract and returns the resulting Info. If any
+// expression fails to type-check, Check still returns every expression
+// it could resolve, alongside a non-nil error listing every problem
+// found.
+//
+// Unlike go/types.Check, Check takes no fset: koa's parser does not
+// yet produce a file-set-style position index, so errors are reported
+// without source positions until it does.
+func Check(contract *ast.Contract) (*Info, error) {
+	c := &checker{
+		info:  newInfo(),
+		funcs: make(map[string]*ast.FunctionLiteral, len(contract.Functions)),
+	}
+
+	// A first pass over every signature lets a call to a function
+	// declared later in the contract - or one that calls itself -
+	// still type-check correctly.
+	for _, fn := range contract.Functions {
+		c.funcs[fn.Name] = fn
+	}
+
+	for _, fn := range contract.Functions {
+		c.checkFunc(fn)
+	}
+
+	if len(c.errs) > 0 {
+		return c.info, c.errs
+	}
+	return c.info, nil
+}
+
+func (c *checker) checkFunc(fn *ast.FunctionLiteral) {
+	scope := NewScope(nil)
+	c.info.Scopes[fn] = scope
+
+	for _, p := range fn.Parameters {
+		scope.Define(p.Identifier.Value, p.Type)
+		c.info.Defs[p.Identifier] = paramSymbol(p)
+	}
+
+	c.checkBlock(fn.Body, scope, fn.ReturnType)
+}
+
+func (c *checker) checkBlock(block *ast.BlockStatement, outer *Scope, retType ast.DataStructure) {
+	scope := NewScope(outer)
+	c.info.Scopes[block] = scope
+
+	for _, stmt := range block.Statements {
+		c.checkStmt(stmt, scope, retType)
+	}
+}
+
+func (c *checker) checkStmt(stmt ast.Statement, scope *Scope, retType ast.DataStructure) {
+	switch s := stmt.(type) {
+	case *ast.AssignStatement:
+		valType := c.checkExpr(s.Value, scope)
+		if valType != s.Type {
+			c.errorf("cannot assign %s value to %s variable %q", valType.String(), s.Type.String(), s.Variable.String())
+		}
+		scope.Define(s.Variable.String(), s.Type)
+		c.info.Defs[s.Variable] = identSymbol(s.Variable, s.Type)
+
+	case *ast.ReturnStatement:
+		if s.ReturnValue == nil {
+			if retType != ast.VoidType {
+				c.errorf("missing return value, function expects %s", retType.String())
+			}
+			return
+		}
+		valType := c.checkExpr(s.ReturnValue, scope)
+		if valType != retType {
+			c.errorf("cannot return %s value from function returning %s", valType.String(), retType.String())
+		}
+
+	case *ast.IfStatement:
+		condType := c.checkExpr(s.Condition, scope)
+		if condType != ast.BoolType {
+			c.errorf("if condition must be %s, got %s", ast.BoolType.String(), condType.String())
+		}
+		c.checkBlock(s.Consequence, scope, retType)
+		if s.Alternative != nil {
+			c.checkBlock(s.Alternative, scope, retType)
+		}
+
+	case *ast.ExpressionStatement:
+		c.checkExpr(s.Expr, scope)
+
+	default:
+		c.errorf("unsupported statement %T", stmt)
+	}
+}
+
+func (c *checker) checkExpr(expr ast.Expression, scope *Scope) ast.DataStructure {
+	switch e := expr.(type) {
+	case *ast.IntegerLiteral:
+		c.info.Types[e] = ast.IntType
+		return ast.IntType
+
+	case *ast.Identifier:
+		t, ok := scope.Lookup(e.Value)
+		if !ok {
+			c.errorf("undefined variable %q", e.Value)
+			return ast.VoidType
+		}
+		c.info.Types[e] = t
+		c.info.Uses[e] = identSymbol(e, t)
+		return t
+
+	case *ast.InfixExpression:
+		left := c.checkExpr(e.Left, scope)
+		right := c.checkExpr(e.Right, scope)
+		if left != right {
+			c.errorf("mismatched operand types %s %s %s", left.String(), e.Operator.String(), right.String())
+			c.info.Types[e] = ast.VoidType
+			return ast.VoidType
+		}
+
+		// Comparison operators always yield a bool, whatever the
+		// operand type; only the arithmetic operators pass the
+		// operand type through.
+		var result ast.DataStructure
+		switch e.Operator.String() {
+		case "==", "!=", "<", ">", "<=", ">=":
+			result = ast.BoolType
+		default:
+			result = left
+		}
+		c.info.Types[e] = result
+		return result
+
+	case *ast.CallExpression:
+		name := e.Function.String()
+		fn, ok := c.funcs[name]
+		if !ok {
+			c.errorf("call to undefined function %q", name)
+			for _, arg := range e.Arguments {
+				c.checkExpr(arg, scope)
+			}
+			c.info.Types[e] = ast.VoidType
+			return ast.VoidType
+		}
+
+		if len(e.Arguments) != len(fn.Parameters) {
+			c.errorf("function %q expects %d argument(s), got %d", name, len(fn.Parameters), len(e.Arguments))
+		}
+		for i, arg := range e.Arguments {
+			argType := c.checkExpr(arg, scope)
+			if i < len(fn.Parameters) && argType != fn.Parameters[i].Type {
+				c.errorf("argument %d to %q must be %s, got %s", i, name, fn.Parameters[i].Type.String(), argType.String())
+			}
+		}
+
+		c.info.Types[e] = fn.ReturnType
+		return fn.ReturnType
+
+	default:
+		c.errorf("unsupported expression %T", expr)
+		return ast.VoidType
+	}
+}
+
+func paramSymbol(p *ast.ParameterLiteral) symbol.Symbol {
+	switch p.Type {
+	case ast.StringType:
+		return &symbol.String{Identifier: p.Identifier}
+	case ast.BoolType:
+		return &symbol.Boolean{Identifier: p.Identifier}
+	default:
+		return &symbol.Integer{Identifier: p.Identifier}
+	}
+}
+
+func identSymbol(id *ast.Identifier, t ast.DataStructure) symbol.Symbol {
+	switch t {
+	case ast.StringType:
+		return &symbol.String{Identifier: id}
+	case ast.BoolType:
+		return &symbol.Boolean{Identifier: id}
+	default:
+		return &symbol.Integer{Identifier: id}
+	}
+}
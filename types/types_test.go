@@ -0,0 +1,120 @@
+/*
+ * Copyright 2018 De-labtory
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ * https://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package types
+
+import (
+	"testing"
+
+	"github.com/DE-labtory/koa/ast"
+)
+
+// TestCheckCallExpressionAssign makes sure assigning a non-void
+// function's result - e.g. `r int = add(1, 2)` - type-checks against
+// the callee's declared return type instead of being hard-coded to
+// VoidType.
+func TestCheckCallExpressionAssign(t *testing.T) {
+	add := &ast.FunctionLiteral{
+		Name:       "add",
+		ReturnType: ast.IntType,
+		Parameters: []*ast.ParameterLiteral{
+			{Type: ast.IntType, Identifier: &ast.Identifier{Value: "a"}},
+			{Type: ast.IntType, Identifier: &ast.Identifier{Value: "b"}},
+		},
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.ReturnStatement{
+					ReturnValue: &ast.InfixExpression{
+						Left:     &ast.Identifier{Value: "a"},
+						Operator: ast.Plus,
+						Right:    &ast.Identifier{Value: "b"},
+					},
+				},
+			},
+		},
+	}
+
+	caller := &ast.FunctionLiteral{
+		Name:       "caller",
+		ReturnType: ast.VoidType,
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.AssignStatement{
+					Type:     ast.IntType,
+					Variable: &ast.Identifier{Value: "r"},
+					Value: &ast.CallExpression{
+						Function: &ast.Identifier{Value: "add"},
+						Arguments: []ast.Expression{
+							&ast.IntegerLiteral{Value: 1},
+							&ast.IntegerLiteral{Value: 2},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	contract := &ast.Contract{Functions: []*ast.FunctionLiteral{add, caller}}
+
+	info, err := Check(contract)
+	if err != nil {
+		t.Fatalf("Check() returned unexpected error: %v", err)
+	}
+
+	call := caller.Body.Statements[0].(*ast.AssignStatement).Value
+	if got := info.Types[call]; got != ast.IntType {
+		t.Errorf("call expression type = %s, want %s", got.String(), ast.IntType.String())
+	}
+}
+
+// TestCheckCallExpressionArgMismatch makes sure a wrong argument count
+// is reported, not silently accepted.
+func TestCheckCallExpressionArgMismatch(t *testing.T) {
+	add := &ast.FunctionLiteral{
+		Name:       "add",
+		ReturnType: ast.IntType,
+		Parameters: []*ast.ParameterLiteral{
+			{Type: ast.IntType, Identifier: &ast.Identifier{Value: "a"}},
+			{Type: ast.IntType, Identifier: &ast.Identifier{Value: "b"}},
+		},
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.ReturnStatement{ReturnValue: &ast.Identifier{Value: "a"}},
+			},
+		},
+	}
+
+	caller := &ast.FunctionLiteral{
+		Name:       "caller",
+		ReturnType: ast.VoidType,
+		Body: &ast.BlockStatement{
+			Statements: []ast.Statement{
+				&ast.ExpressionStatement{
+					Expr: &ast.CallExpression{
+						Function:  &ast.Identifier{Value: "add"},
+						Arguments: []ast.Expression{&ast.IntegerLiteral{Value: 1}},
+					},
+				},
+			},
+		},
+	}
+
+	contract := &ast.Contract{Functions: []*ast.FunctionLiteral{add, caller}}
+
+	if _, err := Check(contract); err == nil {
+		t.Fatalf("Check() with a wrong argument count returned no error")
+	}
+}